@@ -34,7 +34,17 @@ const (
 // ACH contains the structures of a parsed ACH File.
 type ACH struct {
 	FileHeader  FileHeaderRecord
-	BatchHeader BatchHeaderRecord
+	Batches     []Batch
+	IATBatches  []IATBatch
+	FileControl FileControlRecord
+}
+
+// Batch groups the entries originated under a single BatchHeaderRecord along
+// with the BatchControlRecord that totals them.
+type Batch struct {
+	Header  BatchHeaderRecord
+	Entries []EntryDetailRecord
+	Control BatchControlRecord
 }
 
 // FileHeaderRecord designate physical file characteristics and identify
@@ -196,13 +206,11 @@ type EntryDetailRecord struct {
 	DiscretionaryData              string
 	AddendaRecordIndicator         string
 	TraceNumber                    string
-	Addenda                        string
-}
 
-// AddendaRecord provides business transaction information in a machine
-// readable format. It is usually formatted according to ANSI, ASC, X12 Standard
-type AddendaRecord struct {
-	// TODO implement structure
+	// Addendas holds the '7' records associated with this entry, in file
+	// order. See the Addenda interface for the addenda types this package
+	// understands.
+	Addendas []Addenda
 }
 
 // BatchControlRecord contains entry counts, dollar total and has totals for all
@@ -0,0 +1,490 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AddendaTypeCode identifies which of the addenda record layouts a '7'
+// record uses, per its position 2-3 Addenda Type Code.
+type AddendaTypeCode string
+
+// Addenda Type Codes this package understands.
+const (
+	AddendaType02 AddendaTypeCode = "02" // POS/MTE terminal information
+	AddendaType05 AddendaTypeCode = "05" // free-form payment-related information (PPD/CCD/CTX)
+	AddendaType10 AddendaTypeCode = "10" // IAT: transaction type code and foreign payment amount
+	AddendaType11 AddendaTypeCode = "11" // IAT: originator name and street address
+	AddendaType12 AddendaTypeCode = "12" // IAT: originator city/state/province and country/postal code
+	AddendaType13 AddendaTypeCode = "13" // IAT: originating DFI name and identification
+	AddendaType14 AddendaTypeCode = "14" // IAT: receiving DFI name and identification
+	AddendaType15 AddendaTypeCode = "15" // IAT: receiver identification and street address
+	AddendaType16 AddendaTypeCode = "16" // IAT: receiver city/state/province and country/postal code
+	AddendaType17 AddendaTypeCode = "17" // IAT: remittance and other free-form information
+	AddendaType98 AddendaTypeCode = "98" // Notification of Change
+	AddendaType99 AddendaTypeCode = "99" // Return
+)
+
+// Addenda is implemented by every addenda ('7' record) layout this package
+// understands. TypeCode reports which layout a given value uses, so callers
+// can type-switch on it.
+type Addenda interface {
+	TypeCode() AddendaTypeCode
+	String() string
+}
+
+// parseAddenda dispatches a '7' record line to the Addenda implementation
+// its Addenda Type Code (positions 2-3) selects.
+func parseAddenda(line string) (Addenda, error) {
+	if len(line) < recordLength {
+		return nil, fmt.Errorf("ach: addenda record is %d characters, want %d", len(line), recordLength)
+	}
+	switch AddendaTypeCode(slice(line, 2, 4)) {
+	case AddendaType02:
+		a := &Addenda02{}
+		a.parse(line)
+		return a, nil
+	case AddendaType05:
+		a := &Addenda05{}
+		a.parse(line)
+		return a, nil
+	case AddendaType10:
+		a := &Addenda10{}
+		a.parse(line)
+		return a, nil
+	case AddendaType11:
+		a := &Addenda11{}
+		a.parse(line)
+		return a, nil
+	case AddendaType12:
+		a := &Addenda12{}
+		a.parse(line)
+		return a, nil
+	case AddendaType13:
+		a := &Addenda13{}
+		a.parse(line)
+		return a, nil
+	case AddendaType14:
+		a := &Addenda14{}
+		a.parse(line)
+		return a, nil
+	case AddendaType15:
+		a := &Addenda15{}
+		a.parse(line)
+		return a, nil
+	case AddendaType16:
+		a := &Addenda16{}
+		a.parse(line)
+		return a, nil
+	case AddendaType17:
+		a := &Addenda17{}
+		a.parse(line)
+		return a, nil
+	case AddendaType98:
+		a := &Addenda98{}
+		a.parse(line)
+		return a, nil
+	case AddendaType99:
+		a := &Addenda99{}
+		a.parse(line)
+		return a, nil
+	default:
+		return nil, fmt.Errorf("ach: unknown addenda type code %q", slice(line, 2, 4))
+	}
+}
+
+// Addenda02 carries point-of-sale (POS) or machine transfer entry (MTE)
+// terminal information for the preceding Entry Detail Record.
+type Addenda02 struct {
+	ReferenceInformationOne    string
+	ReferenceInformationTwo    string
+	TerminalIdentificationCode string
+	TransactionSerialNumber    string
+	TransactionDate            string
+	TerminalLocation           string
+	TerminalCity               string
+	TerminalState              string
+	TraceNumber                string
+}
+
+func (a Addenda02) TypeCode() AddendaTypeCode { return AddendaType02 }
+
+func (a *Addenda02) parse(line string) {
+	a.ReferenceInformationOne = slice(line, 4, 11)
+	a.ReferenceInformationTwo = slice(line, 11, 15)
+	a.TerminalIdentificationCode = slice(line, 15, 21)
+	a.TransactionSerialNumber = slice(line, 21, 27)
+	a.TransactionDate = slice(line, 27, 31)
+	a.TerminalLocation = slice(line, 31, 58)
+	a.TerminalCity = slice(line, 58, 73)
+	a.TerminalState = slice(line, 73, 75)
+	a.TraceNumber = slice(line, 80, 95)
+}
+
+func (a Addenda02) String() string {
+	return entryAgendaPos + string(AddendaType02) +
+		alpha(a.ReferenceInformationOne, 7) +
+		alpha(a.ReferenceInformationTwo, 4) +
+		alpha(a.TerminalIdentificationCode, 6) +
+		alpha(a.TransactionSerialNumber, 6) +
+		alpha(a.TransactionDate, 4) +
+		alpha(a.TerminalLocation, 27) +
+		alpha(a.TerminalCity, 15) +
+		alpha(a.TerminalState, 2) +
+		alpha("", 5) +
+		numeric(a.TraceNumber, 15)
+}
+
+// Addenda05 carries free-form payment-related information for PPD, CCD, and
+// CTX entries. CTX batches may split one ANSI ASC X12 transaction set
+// across up to 9999 of these; see X12PaymentRelatedInformation.
+type Addenda05 struct {
+	PaymentRelatedInformation string
+	AddendaSequenceNumber     string
+	EntryDetailSequenceNumber string
+}
+
+func (a Addenda05) TypeCode() AddendaTypeCode { return AddendaType05 }
+
+func (a *Addenda05) parse(line string) {
+	a.PaymentRelatedInformation = slice(line, 4, 84)
+	a.AddendaSequenceNumber = slice(line, 84, 88)
+	a.EntryDetailSequenceNumber = slice(line, 88, 95)
+}
+
+func (a Addenda05) String() string {
+	return entryAgendaPos + string(AddendaType05) +
+		alpha(a.PaymentRelatedInformation, 80) +
+		numeric(a.AddendaSequenceNumber, 4) +
+		numeric(a.EntryDetailSequenceNumber, 7)
+}
+
+// Addenda10 carries the foreign payment amount and transaction type code
+// for an IAT entry.
+type Addenda10 struct {
+	TransactionTypeCode       string
+	ForeignPaymentAmount      string
+	ForeignTraceNumber        string
+	Name                      string
+	EntryDetailSequenceNumber string
+}
+
+func (a Addenda10) TypeCode() AddendaTypeCode { return AddendaType10 }
+
+func (a *Addenda10) parse(line string) {
+	a.TransactionTypeCode = slice(line, 4, 7)
+	a.ForeignPaymentAmount = slice(line, 7, 17)
+	a.ForeignTraceNumber = slice(line, 17, 39)
+	a.Name = slice(line, 39, 74)
+	a.EntryDetailSequenceNumber = slice(line, 88, 95)
+}
+
+func (a Addenda10) String() string {
+	return entryAgendaPos + string(AddendaType10) +
+		alpha(a.TransactionTypeCode, 3) +
+		numeric(a.ForeignPaymentAmount, 10) +
+		alpha(a.ForeignTraceNumber, 22) +
+		alpha(a.Name, 35) +
+		alpha("", 14) +
+		numeric(a.EntryDetailSequenceNumber, 7)
+}
+
+// Addenda11 carries the originator's name and street address for an IAT
+// entry.
+type Addenda11 struct {
+	OriginatorName            string
+	OriginatorStreetAddress   string
+	EntryDetailSequenceNumber string
+}
+
+func (a Addenda11) TypeCode() AddendaTypeCode { return AddendaType11 }
+
+func (a *Addenda11) parse(line string) {
+	a.OriginatorName = slice(line, 4, 39)
+	a.OriginatorStreetAddress = slice(line, 39, 74)
+	a.EntryDetailSequenceNumber = slice(line, 88, 95)
+}
+
+func (a Addenda11) String() string {
+	return entryAgendaPos + string(AddendaType11) +
+		alpha(a.OriginatorName, 35) +
+		alpha(a.OriginatorStreetAddress, 35) +
+		alpha("", 14) +
+		numeric(a.EntryDetailSequenceNumber, 7)
+}
+
+// Addenda12 carries the originator's city/state/province and country/
+// postal code for an IAT entry.
+type Addenda12 struct {
+	OriginatorCityStateProvince string
+	OriginatorCountryPostalCode string
+	EntryDetailSequenceNumber   string
+}
+
+func (a Addenda12) TypeCode() AddendaTypeCode { return AddendaType12 }
+
+func (a *Addenda12) parse(line string) {
+	a.OriginatorCityStateProvince = slice(line, 4, 39)
+	a.OriginatorCountryPostalCode = slice(line, 39, 74)
+	a.EntryDetailSequenceNumber = slice(line, 88, 95)
+}
+
+func (a Addenda12) String() string {
+	return entryAgendaPos + string(AddendaType12) +
+		alpha(a.OriginatorCityStateProvince, 35) +
+		alpha(a.OriginatorCountryPostalCode, 35) +
+		alpha("", 14) +
+		numeric(a.EntryDetailSequenceNumber, 7)
+}
+
+// Addenda13 carries the originating DFI's name and identification for an
+// IAT entry.
+type Addenda13 struct {
+	OriginatingDFIName              string
+	OriginatingDFIIDNumberQualifier string
+	OriginatingDFIIdentification    string
+	OriginatingDFIBranchCountryCode string
+	EntryDetailSequenceNumber       string
+}
+
+func (a Addenda13) TypeCode() AddendaTypeCode { return AddendaType13 }
+
+func (a *Addenda13) parse(line string) {
+	a.OriginatingDFIName = slice(line, 4, 39)
+	a.OriginatingDFIIDNumberQualifier = slice(line, 39, 41)
+	a.OriginatingDFIIdentification = slice(line, 41, 75)
+	a.OriginatingDFIBranchCountryCode = slice(line, 75, 78)
+	a.EntryDetailSequenceNumber = slice(line, 88, 95)
+}
+
+func (a Addenda13) String() string {
+	return entryAgendaPos + string(AddendaType13) +
+		alpha(a.OriginatingDFIName, 35) +
+		alpha(a.OriginatingDFIIDNumberQualifier, 2) +
+		alpha(a.OriginatingDFIIdentification, 34) +
+		alpha(a.OriginatingDFIBranchCountryCode, 3) +
+		alpha("", 10) +
+		numeric(a.EntryDetailSequenceNumber, 7)
+}
+
+// Addenda14 carries the receiving DFI's name and identification for an IAT
+// entry.
+type Addenda14 struct {
+	ReceivingDFIName              string
+	ReceivingDFIIDNumberQualifier string
+	ReceivingDFIIdentification    string
+	ReceivingDFIBranchCountryCode string
+	EntryDetailSequenceNumber     string
+}
+
+func (a Addenda14) TypeCode() AddendaTypeCode { return AddendaType14 }
+
+func (a *Addenda14) parse(line string) {
+	a.ReceivingDFIName = slice(line, 4, 39)
+	a.ReceivingDFIIDNumberQualifier = slice(line, 39, 41)
+	a.ReceivingDFIIdentification = slice(line, 41, 75)
+	a.ReceivingDFIBranchCountryCode = slice(line, 75, 78)
+	a.EntryDetailSequenceNumber = slice(line, 88, 95)
+}
+
+func (a Addenda14) String() string {
+	return entryAgendaPos + string(AddendaType14) +
+		alpha(a.ReceivingDFIName, 35) +
+		alpha(a.ReceivingDFIIDNumberQualifier, 2) +
+		alpha(a.ReceivingDFIIdentification, 34) +
+		alpha(a.ReceivingDFIBranchCountryCode, 3) +
+		alpha("", 10) +
+		numeric(a.EntryDetailSequenceNumber, 7)
+}
+
+// Addenda15 carries the receiver's identification number and street
+// address for an IAT entry.
+type Addenda15 struct {
+	ReceiverIDNumber          string
+	ReceiverStreetAddress     string
+	EntryDetailSequenceNumber string
+}
+
+func (a Addenda15) TypeCode() AddendaTypeCode { return AddendaType15 }
+
+func (a *Addenda15) parse(line string) {
+	a.ReceiverIDNumber = slice(line, 4, 19)
+	a.ReceiverStreetAddress = slice(line, 19, 54)
+	a.EntryDetailSequenceNumber = slice(line, 88, 95)
+}
+
+func (a Addenda15) String() string {
+	return entryAgendaPos + string(AddendaType15) +
+		alpha(a.ReceiverIDNumber, 15) +
+		alpha(a.ReceiverStreetAddress, 35) +
+		alpha("", 34) +
+		numeric(a.EntryDetailSequenceNumber, 7)
+}
+
+// Addenda16 carries the receiver's city/state/province and country/postal
+// code for an IAT entry.
+type Addenda16 struct {
+	ReceiverCityStateProvince string
+	ReceiverCountryPostalCode string
+	EntryDetailSequenceNumber string
+}
+
+func (a Addenda16) TypeCode() AddendaTypeCode { return AddendaType16 }
+
+func (a *Addenda16) parse(line string) {
+	a.ReceiverCityStateProvince = slice(line, 4, 39)
+	a.ReceiverCountryPostalCode = slice(line, 39, 74)
+	a.EntryDetailSequenceNumber = slice(line, 88, 95)
+}
+
+func (a Addenda16) String() string {
+	return entryAgendaPos + string(AddendaType16) +
+		alpha(a.ReceiverCityStateProvince, 35) +
+		alpha(a.ReceiverCountryPostalCode, 35) +
+		alpha("", 14) +
+		numeric(a.EntryDetailSequenceNumber, 7)
+}
+
+// Addenda17 carries remittance data and other free-form information for an
+// IAT entry, including OFAC screening notations.
+type Addenda17 struct {
+	PaymentRelatedInformation string
+	SequenceNumber            string
+	EntryDetailSequenceNumber string
+}
+
+func (a Addenda17) TypeCode() AddendaTypeCode { return AddendaType17 }
+
+func (a *Addenda17) parse(line string) {
+	a.PaymentRelatedInformation = slice(line, 4, 84)
+	a.SequenceNumber = slice(line, 84, 88)
+	a.EntryDetailSequenceNumber = slice(line, 88, 95)
+}
+
+func (a Addenda17) String() string {
+	return entryAgendaPos + string(AddendaType17) +
+		alpha(a.PaymentRelatedInformation, 80) +
+		numeric(a.SequenceNumber, 4) +
+		numeric(a.EntryDetailSequenceNumber, 7)
+}
+
+// Addenda98 is a Notification of Change: the RDFI returns it to advise the
+// ODFI that a detail it received needs to be corrected (e.g. a closed
+// account migrated to a new one) rather than rejected outright.
+type Addenda98 struct {
+	ChangeCode                 string
+	OriginalEntryTraceNumber   string
+	OriginalRDFIIdentification string
+	CorrectedData              string
+	TraceNumber                string
+}
+
+func (a Addenda98) TypeCode() AddendaTypeCode { return AddendaType98 }
+
+func (a *Addenda98) parse(line string) {
+	a.ChangeCode = slice(line, 4, 7)
+	a.OriginalEntryTraceNumber = slice(line, 7, 22)
+	a.OriginalRDFIIdentification = slice(line, 22, 30)
+	a.CorrectedData = slice(line, 30, 59)
+	a.TraceNumber = slice(line, 80, 95)
+}
+
+func (a Addenda98) String() string {
+	return entryAgendaPos + string(AddendaType98) +
+		alpha(a.ChangeCode, 3) +
+		numeric(a.OriginalEntryTraceNumber, 15) +
+		numeric(a.OriginalRDFIIdentification, 8) +
+		alpha(a.CorrectedData, 29) +
+		alpha("", 21) +
+		numeric(a.TraceNumber, 15)
+}
+
+// Addenda99 is a Return: the RDFI returns it to send an entry back to the
+// ODFI unpaid, along with the reason it was returned.
+type Addenda99 struct {
+	ReturnReasonCode                   string
+	OriginalEntryTraceNumber           string
+	DateOfDeath                        string
+	OriginalReceivingDFIIdentification string
+	AddendaInformation                 string
+	TraceNumber                        string
+}
+
+func (a Addenda99) TypeCode() AddendaTypeCode { return AddendaType99 }
+
+func (a *Addenda99) parse(line string) {
+	a.ReturnReasonCode = slice(line, 4, 7)
+	a.OriginalEntryTraceNumber = slice(line, 7, 22)
+	a.DateOfDeath = slice(line, 22, 28)
+	a.OriginalReceivingDFIIdentification = slice(line, 28, 36)
+	a.AddendaInformation = slice(line, 36, 80)
+	a.TraceNumber = slice(line, 80, 95)
+}
+
+func (a Addenda99) String() string {
+	return entryAgendaPos + string(AddendaType99) +
+		alpha(a.ReturnReasonCode, 3) +
+		numeric(a.OriginalEntryTraceNumber, 15) +
+		alpha(a.DateOfDeath, 6) +
+		numeric(a.OriginalReceivingDFIIdentification, 8) +
+		alpha(a.AddendaInformation, 44) +
+		numeric(a.TraceNumber, 15)
+}
+
+// X12Segment is one ANSI ASC X12 segment: an identifier (e.g. "ISA", "GS",
+// "ST", "BPR") followed by its data elements, in order.
+type X12Segment struct {
+	ID       string
+	Elements []string
+}
+
+// Default element separator and segment terminator ParseX12 uses when the
+// caller passes none. ParseX12 does not read delimiters from an ISA
+// envelope, so these apply whatever the payload holds. These match what
+// NACHA's own CCD+/CTX sample files use.
+const (
+	defaultX12ElementSeparator  = "*"
+	defaultX12SegmentTerminator = "\\"
+)
+
+// ParseX12 parses an ANSI ASC X12 transaction set, returning its segments
+// in order. sep is the element separator and term the segment terminator;
+// an empty sep or term falls back to the NACHA sample-file defaults ("*"
+// and "\").
+func ParseX12(payload, sep, term string) []X12Segment {
+	if sep == "" {
+		sep = defaultX12ElementSeparator
+	}
+	if term == "" {
+		term = defaultX12SegmentTerminator
+	}
+	var segments []X12Segment
+	for _, raw := range strings.Split(payload, term) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		elements := strings.Split(raw, sep)
+		segments = append(segments, X12Segment{ID: elements[0], Elements: elements[1:]})
+	}
+	return segments
+}
+
+// X12PaymentRelatedInformation joins the PaymentRelatedInformation payload
+// of one or more Addenda05 records — CTX batches split a single X12
+// transaction set across up to 9999 of them — and parses the result into
+// its ANSI ASC X12 segments, so CCD+/CTX remittance data can be read
+// machine-wise rather than as an opaque 80-character blob. The segments
+// come back as a flat list in payload order; envelope segments such as
+// ISA, GS and ST are not nested around the segments they enclose.
+func X12PaymentRelatedInformation(addenda ...Addenda05) []X12Segment {
+	var sb strings.Builder
+	for _, a := range addenda {
+		sb.WriteString(a.PaymentRelatedInformation)
+	}
+	return ParseX12(sb.String(), "", "")
+}
@@ -0,0 +1,159 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import (
+	"fmt"
+)
+
+// secRule describes the per-entry constraints NACHA places on a batch for a
+// given Standard Entry Class code: how many addenda records a single entry
+// may carry, and which TransactionCodes it may use. A nil
+// allowedTransactionCodes leaves TransactionCode checking to
+// EntryDetailRecord.Validate's general knownTransactionCodes check.
+type secRule struct {
+	maxAddendaPerEntry      int
+	allowedTransactionCodes map[string]bool
+}
+
+// debitOnlyTransactionCodes is the TransactionCode set NACHA allows for SEC
+// codes that only ever debit the receiver's account: ARC and BOC convert a
+// paper check to a single debit, and TEL authorizes a debit over the phone.
+var debitOnlyTransactionCodes = map[string]bool{"27": true, "28": true, "29": true, "37": true, "38": true, "39": true}
+
+// secRules covers the domestic Standard Entry Class codes this package
+// supports. CTX is the outlier: corporate trade exchange entries can carry
+// up to 9999 addenda records of remittance detail, where every other
+// domestic SEC code allows at most one. IAT batches are validated
+// separately by IATBatch.Validate, since every IAT entry requires its own
+// mandatory sequence of addenda rather than a single optional one.
+var secRules = map[string]secRule{
+	"PPD": {maxAddendaPerEntry: 1},
+	"CCD": {maxAddendaPerEntry: 1},
+	"CTX": {maxAddendaPerEntry: 9999},
+	"WEB": {maxAddendaPerEntry: 1},
+	"TEL": {maxAddendaPerEntry: 1, allowedTransactionCodes: debitOnlyTransactionCodes},
+	"ARC": {maxAddendaPerEntry: 1, allowedTransactionCodes: debitOnlyTransactionCodes},
+	"BOC": {maxAddendaPerEntry: 1, allowedTransactionCodes: debitOnlyTransactionCodes},
+}
+
+// Reserved CompanyEntryDescription values NACHA assigns a specific meaning
+// to, rather than leaving the text to the Originator's discretion.
+const (
+	CompanyEntryDescriptionReversal   = "REVERSAL"
+	CompanyEntryDescriptionReclaim    = "RECLAIM"
+	CompanyEntryDescriptionNonsettled = "NONSETTLED"
+)
+
+// addendaCount returns the number of '7' records attached to entry.
+func addendaCount(entry EntryDetailRecord) int {
+	return len(entry.Addendas)
+}
+
+// Validate checks b's header, every entry (including any Addenda98/
+// Addenda99 it carries), and its control record, including the
+// addenda-count and TransactionCode limits NACHA places on
+// b.Header.StandardEntryClassCode and the requirement that the control
+// record's BatchNumber match the header's. It returns the accumulated
+// ValidateErrors, or nil if b is clean.
+func (b Batch) Validate() error {
+	var errs ValidateErrors
+
+	errs = merge(errs, b.Header.Validate())
+	rule, ok := secRules[b.Header.StandardEntryClassCode]
+	for i, entry := range b.Entries {
+		errs = merge(errs, entry.Validate())
+		if ok {
+			if n := addendaCount(entry); n > rule.maxAddendaPerEntry {
+				errs = append(errs, &FieldError{"EntryDetailRecord", "Addenda", ErrMandatory,
+					fmt.Sprintf("entry %d: %s allows at most %d addenda record(s), got %d",
+						i, b.Header.StandardEntryClassCode, rule.maxAddendaPerEntry, n)})
+			}
+			if rule.allowedTransactionCodes != nil && !rule.allowedTransactionCodes[entry.TransactionCode] {
+				errs = append(errs, &FieldError{"EntryDetailRecord", "TransactionCode", ErrMandatory,
+					fmt.Sprintf("entry %d: %s does not allow transaction code %q",
+						i, b.Header.StandardEntryClassCode, entry.TransactionCode)})
+			}
+		}
+		for _, addenda := range entry.Addendas {
+			switch a := addenda.(type) {
+			case *Addenda98:
+				errs = merge(errs, a.Validate())
+			case *Addenda99:
+				errs = merge(errs, a.Validate())
+			}
+		}
+	}
+	errs = merge(errs, b.Control.Validate())
+	if b.Control.BatchNumber != b.Header.BatchNumber {
+		errs = append(errs, &FieldError{"BatchControlRecord", "BatchNumber", ErrMandatory,
+			fmt.Sprintf("control BatchNumber %q does not match header BatchNumber %q",
+				b.Control.BatchNumber, b.Header.BatchNumber)})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// IATEntry pairs an International ACH Transaction Entry Detail Record with
+// its sequence of IAT addenda records, in NACHA's fixed order: Addenda10
+// through Addenda16, plus the optional remittance-information Addenda17.
+type IATEntry struct {
+	EntryDetail EntryDetailRecord
+	Addenda     []Addenda
+}
+
+// requiredIATAddendaCount is the number of mandatory addenda records NACHA
+// requires on every IAT entry: Addenda10 (transaction type and amounts),
+// Addenda11-12 (originator name/address), Addenda13-14 (originating/
+// receiving DFI name), Addenda15-16 (receiver name/address and
+// identification).
+const requiredIATAddendaCount = 7
+
+// IATBatch is a Batch of International ACH Transaction entries. Unlike a
+// domestic batch, where a single optional Addenda05 record carries
+// free-form remittance text, NACHA requires every IATEntry to carry its
+// full mandatory sequence of addenda records: foreign-exchange
+// information, OFAC screening indicators, and originator/receiver name and
+// address.
+type IATBatch struct {
+	Header  BatchHeaderRecord
+	Entries []IATEntry
+	Control BatchControlRecord
+}
+
+// Validate checks b's header and control records, and that every entry
+// carries its full mandatory sequence of IAT addenda records. It returns
+// the accumulated ValidateErrors, or nil if b is clean.
+func (b IATBatch) Validate() error {
+	var errs ValidateErrors
+
+	errs = merge(errs, b.Header.Validate())
+	for i, entry := range b.Entries {
+		errs = merge(errs, entry.EntryDetail.Validate())
+		if len(entry.Addenda) < requiredIATAddendaCount {
+			errs = append(errs, &FieldError{"IATEntry", "Addenda", ErrMandatory,
+				fmt.Sprintf("entry %d: IAT entries require %d addenda records, got %d",
+					i, requiredIATAddendaCount, len(entry.Addenda))})
+		} else {
+			for j, addenda := range entry.Addenda[:requiredIATAddendaCount] {
+				want := AddendaTypeCode(fmt.Sprintf("%d", 10+j))
+				if addenda == nil || addenda.TypeCode() != want {
+					errs = append(errs, &FieldError{"IATEntry", "Addenda", ErrMandatory,
+						fmt.Sprintf("entry %d: addenda %d must be Addenda%s", i, j, want)})
+					break
+				}
+			}
+		}
+	}
+	errs = merge(errs, b.Control.Validate())
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,117 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestBatchValidateRejectsDisallowedTransactionCode checks that a TEL entry
+// carrying a credit TransactionCode is rejected, since TEL only ever debits
+// the receiver's account.
+func TestBatchValidateRejectsDisallowedTransactionCode(t *testing.T) {
+	b := Batch{
+		Header: BatchHeaderRecord{
+			ServiceClassCode:              "220",
+			StandardEntryClassCode:        "TEL",
+			EffectiveEntryDate:            "260101",
+			OriginatingOdfiIdentification: "12345678",
+			BatchNumber:                   "1",
+		},
+		Entries: []EntryDetailRecord{{
+			TransactionCode:  "22",
+			RoutingNumber:    "12345678",
+			CheckDigit:       "0",
+			DfiAccountNumber: "1",
+			Amount:           "100",
+			IndividualName:   "RECEIVER",
+			TraceNumber:      "123456780000001",
+		}},
+		Control: BatchControlRecord{ServiceClassCode: "220", BatchNumber: "1"},
+	}
+
+	err := b.Validate()
+	if err == nil {
+		t.Fatal("Validate did not reject a TEL entry with a credit transaction code")
+	}
+	ve, _ := err.(ValidateErrors)
+	found := false
+	for _, fe := range ve {
+		if fe.Record == "EntryDetailRecord" && fe.Field == "TransactionCode" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Validate did not flag the TEL entry's TransactionCode: %v", err)
+	}
+}
+
+// TestIATBatchRoundTrip checks that an IATBatch survives a Writer/Reader
+// round trip with its structured addenda intact.
+func TestIATBatchRoundTrip(t *testing.T) {
+	iatBatch := IATBatch{
+		Header: BatchHeaderRecord{
+			ServiceClassCode:              "220",
+			StandardEntryClassCode:        "IAT",
+			EffectiveEntryDate:            "260101",
+			OriginatingOdfiIdentification: "12345678",
+			BatchNumber:                   "1",
+		},
+		Entries: []IATEntry{{
+			EntryDetail: EntryDetailRecord{
+				TransactionCode:  "22",
+				RoutingNumber:    "12345678",
+				CheckDigit:       "0",
+				DfiAccountNumber: "1",
+				Amount:           "100",
+				IndividualName:   "RECEIVER",
+				TraceNumber:      "123456780000001",
+			},
+			Addenda: []Addenda{
+				&Addenda10{TransactionTypeCode: "ANN"},
+				&Addenda11{OriginatorName: "ORIGINATOR"},
+				&Addenda12{OriginatorCityStateProvince: "TORONTO*ON"},
+				&Addenda13{OriginatingDFIName: "ODFI BANK"},
+				&Addenda14{ReceivingDFIName: "RDFI BANK"},
+				&Addenda15{ReceiverIDNumber: "RECEIVER"},
+				&Addenda16{ReceiverCityStateProvince: "BOSTON*MA"},
+			},
+		}},
+		Control: BatchControlRecord{ServiceClassCode: "220", BatchNumber: "1"},
+	}
+
+	file := ACH{
+		FileHeader: FileHeaderRecord{BlockingFactor: "10"},
+		IATBatches: []IATBatch{iatBatch},
+	}
+
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write(file); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got, err := NewReader(&buf).Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	if len(got.IATBatches) != 1 {
+		t.Fatalf("got %d IATBatches, want 1", len(got.IATBatches))
+	}
+	entries := got.IATBatches[0].Entries
+	if len(entries) != 1 {
+		t.Fatalf("got %d IATEntries, want 1", len(entries))
+	}
+	if len(entries[0].Addenda) != requiredIATAddendaCount {
+		t.Fatalf("got %d addenda, want %d", len(entries[0].Addenda), requiredIATAddendaCount)
+	}
+	if a, ok := entries[0].Addenda[0].(*Addenda10); !ok || a.TransactionTypeCode != "ANN" {
+		t.Fatalf("first addenda = %#v, want *Addenda10{TransactionTypeCode: \"ANN\"}", entries[0].Addenda[0])
+	}
+	if err := got.IATBatches[0].Validate(); err != nil {
+		t.Fatalf("round-tripped IATBatch failed Validate: %v", err)
+	}
+}
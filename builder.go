@@ -0,0 +1,200 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import (
+	"strconv"
+	"strings"
+)
+
+// blockingFactor is the number of records NACHA packs into a single block;
+// BlockCount is always the total record count rounded up to a multiple of
+// this and then divided by it.
+const blockingFactor = 10
+
+// creditTransactionCodes and debitTransactionCodes partition
+// knownTransactionCodes by which BatchControlRecord/FileControlRecord
+// dollar total they contribute to: checking/savings credits (and their
+// prenote and zero-dollar variants) on one side, debits on the other.
+var creditTransactionCodes = map[string]bool{"22": true, "23": true, "24": true, "32": true, "33": true, "34": true}
+var debitTransactionCodes = map[string]bool{"27": true, "28": true, "29": true, "37": true, "38": true, "39": true}
+
+// routingHash returns the numeric value of an entry's 8-digit RoutingNumber,
+// for summing into an EntryHash.
+func routingHash(routingNumber string) int64 {
+	n, _ := strconv.ParseInt(strings.TrimSpace(routingNumber), 10, 64)
+	return n
+}
+
+// entryTotals sums the figures a BatchControlRecord or FileControlRecord
+// must report over entries: the addenda-inclusive record count, the
+// EntryHash (truncated to its low 10 digits per NACHA convention), and the
+// debit/credit dollar totals by TransactionCode.
+func entryTotals(entries []EntryDetailRecord) (entryAddendaCount int, entryHash, totalDebit, totalCredit int64) {
+	for _, entry := range entries {
+		entryAddendaCount += 1 + len(entry.Addendas)
+		entryHash += routingHash(entry.RoutingNumber)
+		amount, _ := strconv.ParseInt(strings.TrimSpace(entry.Amount), 10, 64)
+		switch {
+		case creditTransactionCodes[entry.TransactionCode]:
+			totalCredit += amount
+		case debitTransactionCodes[entry.TransactionCode]:
+			totalDebit += amount
+		}
+	}
+	entryHash %= 10000000000
+	return entryAddendaCount, entryHash, totalDebit, totalCredit
+}
+
+// blockCount rounds lines up to a multiple of blockingFactor and returns
+// the number of blocks that makes.
+func blockCount(lines int) int {
+	if rem := lines % blockingFactor; rem != 0 {
+		lines += blockingFactor - rem
+	}
+	return lines / blockingFactor
+}
+
+// BatchBuilder assembles a Batch, computing the BatchControlRecord fields
+// callers should never hand-fill: EntryAddendaCount, EntryHash, and the
+// debit/credit dollar totals. Entries are added as given; their TraceNumber
+// is assigned once the batch is attached to a FileBuilder, whose sequence is
+// shared across every batch in the file (see FileBuilder.AddBatch).
+type BatchBuilder struct {
+	header  BatchHeaderRecord
+	entries []EntryDetailRecord
+}
+
+// NewBatchBuilder starts a BatchBuilder from header. header.RecordType and
+// header.BatchNumber are overwritten by Build; every other field is used as
+// given.
+func NewBatchBuilder(header BatchHeaderRecord) *BatchBuilder {
+	return &BatchBuilder{header: header}
+}
+
+// AddEntry appends entry to the batch.
+func (bb *BatchBuilder) AddEntry(entry EntryDetailRecord) *BatchBuilder {
+	bb.entries = append(bb.entries, entry)
+	return bb
+}
+
+// MarkReversal sets the header's CompanyEntryDescription to NACHA's
+// reserved "REVERSAL" value, which it requires on any batch of reversing
+// entries.
+func (bb *BatchBuilder) MarkReversal() *BatchBuilder {
+	bb.header.CompanyEntryDescription = CompanyEntryDescriptionReversal
+	return bb
+}
+
+// MarkReclaim sets the header's CompanyEntryDescription to NACHA's reserved
+// "RECLAIM" value, which it requires on any batch of reclamation entries.
+func (bb *BatchBuilder) MarkReclaim() *BatchBuilder {
+	bb.header.CompanyEntryDescription = CompanyEntryDescriptionReclaim
+	return bb
+}
+
+// MarkNonsettled sets the header's CompanyEntryDescription to NACHA's
+// reserved "NONSETTLED" value, which it requires on any batch of entries
+// that could not settle.
+func (bb *BatchBuilder) MarkNonsettled() *BatchBuilder {
+	bb.header.CompanyEntryDescription = CompanyEntryDescriptionNonsettled
+	return bb
+}
+
+// Build assigns batchNumber and RecordType to the header and a matching
+// BatchControlRecord, assigns each entry's RecordType, AddendaRecordIndicator
+// (based on whether it carries any Addendas), and TraceNumber (from
+// traceSeq, the ODFI's 8-digit routing prefix followed by a zero-padded
+// sequence number, incremented as traceSeq is shared across every batch in
+// the file), and returns the finished Batch.
+func (bb *BatchBuilder) Build(batchNumber int, traceSeq *int) Batch {
+	header := bb.header
+	header.RecordType = batchPos
+	header.BatchNumber = strconv.Itoa(batchNumber)
+
+	entries := make([]EntryDetailRecord, len(bb.entries))
+	for i, entry := range bb.entries {
+		*traceSeq++
+		entry.RecordType = entryDetailPos
+		entry.TraceNumber = numeric(header.OriginatingOdfiIdentification, 8) + numeric(strconv.Itoa(*traceSeq), 7)
+		if len(entry.Addendas) > 0 {
+			entry.AddendaRecordIndicator = "1"
+		} else {
+			entry.AddendaRecordIndicator = "0"
+		}
+		entries[i] = entry
+	}
+
+	entryAddendaCount, entryHash, totalDebit, totalCredit := entryTotals(entries)
+
+	control := BatchControlRecord{
+		RecordType:                   batchControlPos,
+		ServiceClassCode:             header.ServiceClassCode,
+		EntryAddendaCount:            strconv.Itoa(entryAddendaCount),
+		EntryHash:                    strconv.FormatInt(entryHash, 10),
+		TotalDebitEntryDollarAmount:  strconv.FormatInt(totalDebit, 10),
+		TotalCreditEntryDollarAmount: strconv.FormatInt(totalCredit, 10),
+		CompanyIdentification:        header.CompanyIdentification,
+		RoutingNumber:                header.OriginatingOdfiIdentification,
+		BatchNumber:                  header.BatchNumber,
+	}
+
+	return Batch{Header: header, Entries: entries, Control: control}
+}
+
+// FileBuilder assembles an ACH file, computing the FileControlRecord fields
+// callers should never hand-fill: BatchCount, BlockCount, EntryAddendaCount,
+// EntryHash, and the debit/credit dollar totals across every batch. It also
+// assigns TraceNumbers from a single sequence shared across every batch, so
+// entries from different batches in the same file never collide.
+type FileBuilder struct {
+	header   FileHeaderRecord
+	batches  []Batch
+	traceSeq int
+}
+
+// NewFileBuilder starts a FileBuilder from header.
+func NewFileBuilder(header FileHeaderRecord) *FileBuilder {
+	return &FileBuilder{header: header}
+}
+
+// AddBatch builds bb, assigning it the next sequential BatchNumber and
+// continuing the file's shared TraceNumber sequence, and appends the result
+// to the file.
+func (fb *FileBuilder) AddBatch(bb *BatchBuilder) *FileBuilder {
+	fb.batches = append(fb.batches, bb.Build(len(fb.batches)+1, &fb.traceSeq))
+	return fb
+}
+
+// Build assigns RecordType to the header, computes the FileControlRecord,
+// and returns the finished ACH file.
+func (fb *FileBuilder) Build() ACH {
+	header := fb.header
+	header.RecordType = headerPos
+
+	var entries []EntryDetailRecord
+	lines := 2 // file header + file control
+	for _, batch := range fb.batches {
+		lines += 2 // batch header + batch control
+		for _, entry := range batch.Entries {
+			lines += 1 + len(entry.Addendas)
+		}
+		entries = append(entries, batch.Entries...)
+	}
+
+	entryAddendaCount, entryHash, totalDebit, totalCredit := entryTotals(entries)
+
+	control := FileControlRecord{
+		RecordType:                         fileControlPos,
+		BatchCount:                         strconv.Itoa(len(fb.batches)),
+		BlockCount:                         strconv.Itoa(blockCount(lines)),
+		EntryAddendaCount:                  strconv.Itoa(entryAddendaCount),
+		EntryHash:                          strconv.FormatInt(entryHash, 10),
+		TotalDebitEntryDollarAmountInFile:  strconv.FormatInt(totalDebit, 10),
+		TotalCreditEntryDollarAmountInFile: strconv.FormatInt(totalCredit, 10),
+	}
+
+	return ACH{FileHeader: header, Batches: fb.batches, FileControl: control}
+}
@@ -0,0 +1,139 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import "testing"
+
+// TestFileBuilderTraceNumbersUniqueAcrossBatches checks that entries in
+// different batches originated by the same ODFI get distinct TraceNumbers,
+// since ACH.MatchReturn joins a return back to its entry by TraceNumber.
+func TestFileBuilderTraceNumbersUniqueAcrossBatches(t *testing.T) {
+	header := BatchHeaderRecord{OriginatingOdfiIdentification: "12345678"}
+
+	fb := NewFileBuilder(FileHeaderRecord{})
+	fb.AddBatch(NewBatchBuilder(header).AddEntry(EntryDetailRecord{}))
+	fb.AddBatch(NewBatchBuilder(header).AddEntry(EntryDetailRecord{}))
+	file := fb.Build()
+
+	if len(file.Batches) != 2 {
+		t.Fatalf("got %d batches, want 2", len(file.Batches))
+	}
+	first := file.Batches[0].Entries[0].TraceNumber
+	second := file.Batches[1].Entries[0].TraceNumber
+	if first == second {
+		t.Fatalf("entries in different batches got the same TraceNumber %q", first)
+	}
+	if want := "123456780000001"; first != want {
+		t.Fatalf("first entry TraceNumber = %q, want %q", first, want)
+	}
+	if want := "123456780000002"; second != want {
+		t.Fatalf("second entry TraceNumber = %q, want %q", second, want)
+	}
+}
+
+// TestFileBuilderHappyPath checks that Build computes consistent control
+// totals for a small, two-entry, one-batch file.
+func TestFileBuilderHappyPath(t *testing.T) {
+	header := BatchHeaderRecord{
+		ServiceClassCode:              "200",
+		OriginatingOdfiIdentification: "12345678",
+		CompanyIdentification:         "1234567890",
+	}
+
+	bb := NewBatchBuilder(header).
+		AddEntry(EntryDetailRecord{TransactionCode: "22", RoutingNumber: "12345678", Amount: "1000"}).
+		AddEntry(EntryDetailRecord{TransactionCode: "27", RoutingNumber: "12345678", Amount: "400"})
+
+	file := NewFileBuilder(FileHeaderRecord{}).AddBatch(bb).Build()
+
+	if got, want := len(file.Batches), 1; got != want {
+		t.Fatalf("got %d batches, want %d", got, want)
+	}
+	control := file.Batches[0].Control
+	if got, want := control.EntryAddendaCount, "2"; got != want {
+		t.Errorf("BatchControlRecord.EntryAddendaCount = %q, want %q", got, want)
+	}
+	if got, want := control.TotalCreditEntryDollarAmount, "1000"; got != want {
+		t.Errorf("BatchControlRecord.TotalCreditEntryDollarAmount = %q, want %q", got, want)
+	}
+	if got, want := control.TotalDebitEntryDollarAmount, "400"; got != want {
+		t.Errorf("BatchControlRecord.TotalDebitEntryDollarAmount = %q, want %q", got, want)
+	}
+
+	if got, want := file.FileControl.BatchCount, "1"; got != want {
+		t.Errorf("FileControlRecord.BatchCount = %q, want %q", got, want)
+	}
+	if got, want := file.FileControl.EntryAddendaCount, "2"; got != want {
+		t.Errorf("FileControlRecord.EntryAddendaCount = %q, want %q", got, want)
+	}
+}
+
+// TestFileBuilderOutputValidates checks that a file assembled purely
+// through FileBuilder/BatchBuilder, as documented, passes its own
+// ACH.Validate(): the builders must fill in RecordType on every record they
+// construct, and AddendaRecordIndicator on every entry that carries
+// Addendas.
+func TestFileBuilderOutputValidates(t *testing.T) {
+	header := BatchHeaderRecord{
+		ServiceClassCode:              "200",
+		StandardEntryClassCode:        "PPD",
+		EffectiveEntryDate:            "260101",
+		OriginatingOdfiIdentification: "12345678",
+		CompanyIdentification:         "1234567890",
+	}
+
+	bb := NewBatchBuilder(header).
+		AddEntry(EntryDetailRecord{
+			TransactionCode:  "22",
+			RoutingNumber:    "12345678",
+			CheckDigit:       "0",
+			DfiAccountNumber: "1",
+			Amount:           "1000",
+			IndividualName:   "RECEIVER ONE",
+			Addendas:         []Addenda{&Addenda05{PaymentRelatedInformation: "memo"}},
+		}).
+		AddEntry(EntryDetailRecord{
+			TransactionCode:  "27",
+			RoutingNumber:    "12345678",
+			CheckDigit:       "0",
+			DfiAccountNumber: "2",
+			Amount:           "400",
+			IndividualName:   "RECEIVER TWO",
+		})
+
+	fileHeader := FileHeaderRecord{
+		ImmediateDestination: " 123456780",
+		ImmediateOrigin:      " 123456780",
+		FileCreationDate:     "260101",
+		FileCreationTime:     "0000",
+		RecordSize:           "094",
+		BlockingFactor:       "10",
+		FormatCode:           "1",
+	}
+	file := NewFileBuilder(fileHeader).AddBatch(bb).Build()
+
+	entries := file.Batches[0].Entries
+	if got, want := entries[0].AddendaRecordIndicator, "1"; got != want {
+		t.Errorf("entry with addenda: AddendaRecordIndicator = %q, want %q", got, want)
+	}
+	if got, want := entries[1].AddendaRecordIndicator, "0"; got != want {
+		t.Errorf("entry without addenda: AddendaRecordIndicator = %q, want %q", got, want)
+	}
+	if got, want := file.FileHeader.RecordType, headerPos; got != want {
+		t.Errorf("FileHeaderRecord.RecordType = %q, want %q", got, want)
+	}
+	if got, want := file.Batches[0].Header.RecordType, batchPos; got != want {
+		t.Errorf("BatchHeaderRecord.RecordType = %q, want %q", got, want)
+	}
+	for i, entry := range entries {
+		if entry.RecordType != entryDetailPos {
+			t.Errorf("entry %d: RecordType = %q, want %q", i, entry.RecordType, entryDetailPos)
+		}
+	}
+
+	if err := file.Validate(); err != nil {
+		t.Fatalf("a file assembled purely through the builder API failed Validate(): %v", err)
+	}
+}
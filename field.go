@@ -0,0 +1,46 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import "strings"
+
+// recordLength is the fixed width, in characters, of every record in a
+// NACHA-formatted ACH file.
+const recordLength = 94
+
+// alpha returns s left-justified and space padded (or truncated) to width
+// characters, per the NACHA convention for alphanumeric fields.
+func alpha(s string, width int) string {
+	if len(s) > width {
+		return s[:width]
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+// numeric returns s right-justified and zero padded (or truncated) to width
+// characters, per the NACHA convention for numeric fields.
+func numeric(s string, width int) string {
+	if len(s) > width {
+		return s[len(s)-width:]
+	}
+	return strings.Repeat("0", width-len(s)) + s
+}
+
+// slice returns the substring of line covering the 1-based, end-exclusive
+// range [start, end), trimmed of trailing spaces. Ranges that fall outside
+// line return an empty string instead of panicking, so short or malformed
+// records parse as blank fields rather than crashing the reader.
+func slice(line string, start, end int) string {
+	if start < 1 {
+		start = 1
+	}
+	if end > len(line)+1 {
+		end = len(line) + 1
+	}
+	if start > len(line) || start >= end {
+		return ""
+	}
+	return strings.TrimRight(line[start-1:end-1], " ")
+}
@@ -0,0 +1,156 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Reader reads records from a NACHA-formatted ACH file.
+type Reader struct {
+	scanner *bufio.Scanner
+}
+
+// NewReader returns a Reader that reads records from r. Records may be
+// separated by CR/LF (or bare LF), or simply concatenated with no
+// terminator at all, as produced by some ACH operators.
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, recordLength), recordLength*1024)
+	scanner.Split(splitRecords)
+	return &Reader{scanner: scanner}
+}
+
+// splitRecords is a bufio.SplitFunc that yields one fixed-width record at a
+// time, whether the source delimits records with CR/LF or not at all.
+func splitRecords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		advance = i + 1
+		if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+			advance++
+		}
+		return advance, data[:i], nil
+	}
+	if len(data) >= recordLength {
+		return recordLength, data[:recordLength], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Read parses the underlying reader in full and returns the resulting ACH
+// file. Filler records (the all-'9' records used to pad a file to a
+// multiple of the blocking factor) are recognized and discarded.
+func (r *Reader) Read() (ACH, error) {
+	file := ACH{}
+	var batch *Batch
+	var iatBatch *IATBatch
+
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+		if line == "" || isFillerRecord(line) {
+			continue
+		}
+		if len(line) < recordLength {
+			return file, fmt.Errorf("ach: record is %d characters, want %d: %q", len(line), recordLength, line)
+		}
+
+		switch line[0:1] {
+		case headerPos:
+			if err := file.FileHeader.parse(line); err != nil {
+				return file, err
+			}
+		case batchPos:
+			header := BatchHeaderRecord{}
+			if err := header.parse(line); err != nil {
+				return file, err
+			}
+			if header.StandardEntryClassCode == "IAT" {
+				iatBatch = &IATBatch{Header: header}
+				batch = nil
+			} else {
+				batch = &Batch{Header: header}
+				iatBatch = nil
+			}
+		case entryDetailPos:
+			ed := EntryDetailRecord{}
+			if err := ed.parse(line); err != nil {
+				return file, err
+			}
+			switch {
+			case iatBatch != nil:
+				iatBatch.Entries = append(iatBatch.Entries, IATEntry{EntryDetail: ed})
+			case batch != nil:
+				batch.Entries = append(batch.Entries, ed)
+			default:
+				return file, fmt.Errorf("ach: entry detail record encountered before a batch header")
+			}
+		case entryAgendaPos:
+			addenda, err := parseAddenda(line)
+			if err != nil {
+				return file, err
+			}
+			switch {
+			case iatBatch != nil && len(iatBatch.Entries) > 0:
+				last := &iatBatch.Entries[len(iatBatch.Entries)-1]
+				last.Addenda = append(last.Addenda, addenda)
+			case batch != nil && len(batch.Entries) > 0:
+				last := &batch.Entries[len(batch.Entries)-1]
+				last.Addendas = append(last.Addendas, addenda)
+			default:
+				return file, fmt.Errorf("ach: addenda record encountered before an entry detail record")
+			}
+		case batchControlPos:
+			switch {
+			case iatBatch != nil:
+				if err := iatBatch.Control.parse(line); err != nil {
+					return file, err
+				}
+				file.IATBatches = append(file.IATBatches, *iatBatch)
+				iatBatch = nil
+			case batch != nil:
+				if err := batch.Control.parse(line); err != nil {
+					return file, err
+				}
+				file.Batches = append(file.Batches, *batch)
+				batch = nil
+			default:
+				return file, fmt.Errorf("ach: batch control record encountered before a batch header")
+			}
+		case fileControlPos:
+			if err := file.FileControl.parse(line); err != nil {
+				return file, err
+			}
+		default:
+			return file, fmt.Errorf("ach: unknown record type %q", line[0:1])
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return file, err
+	}
+	return file, nil
+}
+
+// isFillerRecord reports whether line is one of the all-'9' records NACHA
+// uses to pad a file out to a multiple of the blocking factor.
+func isFillerRecord(line string) bool {
+	if line == "" {
+		return false
+	}
+	for _, r := range line {
+		if r != '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import "fmt"
+
+// parse populates fh from a 94 character File Header Record line.
+func (fh *FileHeaderRecord) parse(line string) error {
+	if len(line) < recordLength {
+		return fmt.Errorf("ach: file header record is %d characters, want %d", len(line), recordLength)
+	}
+	fh.RecordType = slice(line, 1, 2)
+	fh.PriorityCode = slice(line, 2, 4)
+	fh.ImmediateDestination = slice(line, 4, 14)
+	fh.ImmediateOrigin = slice(line, 14, 24)
+	fh.FileCreationDate = slice(line, 24, 30)
+	fh.FileCreationTime = slice(line, 30, 34)
+	fh.FileIdModifier = slice(line, 34, 35)
+	fh.RecordSize = slice(line, 35, 38)
+	fh.BlockingFactor = slice(line, 38, 40)
+	fh.FormatCode = slice(line, 40, 41)
+	fh.ImmediateDestinationName = slice(line, 41, 64)
+	fh.ImmidiateOriginName = slice(line, 64, 87)
+	fh.ReferenceCode = slice(line, 87, 95)
+	return nil
+}
+
+// String renders fh as a 94 character File Header Record line.
+func (fh FileHeaderRecord) String() string {
+	return headerPos +
+		numeric(fh.PriorityCode, 2) +
+		alpha(fh.ImmediateDestination, 10) +
+		alpha(fh.ImmediateOrigin, 10) +
+		numeric(fh.FileCreationDate, 6) +
+		numeric(fh.FileCreationTime, 4) +
+		alpha(fh.FileIdModifier, 1) +
+		numeric(fh.RecordSize, 3) +
+		numeric(fh.BlockingFactor, 2) +
+		numeric(fh.FormatCode, 1) +
+		alpha(fh.ImmediateDestinationName, 23) +
+		alpha(fh.ImmidiateOriginName, 23) +
+		alpha(fh.ReferenceCode, 8)
+}
+
+// parse populates bh from a 94 character Company/Batch Header Record line.
+func (bh *BatchHeaderRecord) parse(line string) error {
+	if len(line) < recordLength {
+		return fmt.Errorf("ach: batch header record is %d characters, want %d", len(line), recordLength)
+	}
+	bh.RecordType = slice(line, 1, 2)
+	bh.ServiceClassCode = slice(line, 2, 5)
+	bh.CompanyName = slice(line, 5, 21)
+	bh.CompanyDiscretionaryData = slice(line, 21, 41)
+	bh.CompanyIdentification = slice(line, 41, 51)
+	bh.StandardEntryClassCode = slice(line, 51, 54)
+	bh.CompanyEntryDescription = slice(line, 54, 64)
+	bh.CompanyDescriptiveDate = slice(line, 64, 70)
+	bh.EffectiveEntryDate = slice(line, 70, 76)
+	bh.SettlementDate = slice(line, 76, 79)
+	bh.OriginatorStatusCode = slice(line, 79, 80)
+	bh.OriginatingOdfiIdentification = slice(line, 80, 88)
+	bh.BatchNumber = slice(line, 88, 95)
+	return nil
+}
+
+// String renders bh as a 94 character Company/Batch Header Record line.
+func (bh BatchHeaderRecord) String() string {
+	return batchPos +
+		numeric(bh.ServiceClassCode, 3) +
+		alpha(bh.CompanyName, 16) +
+		alpha(bh.CompanyDiscretionaryData, 20) +
+		alpha(bh.CompanyIdentification, 10) +
+		alpha(bh.StandardEntryClassCode, 3) +
+		alpha(bh.CompanyEntryDescription, 10) +
+		alpha(bh.CompanyDescriptiveDate, 6) +
+		numeric(bh.EffectiveEntryDate, 6) +
+		numeric(bh.SettlementDate, 3) +
+		numeric(bh.OriginatorStatusCode, 1) +
+		numeric(bh.OriginatingOdfiIdentification, 8) +
+		numeric(bh.BatchNumber, 7)
+}
+
+// parse populates ed from a 94 character Entry Detail Record line.
+func (ed *EntryDetailRecord) parse(line string) error {
+	if len(line) < recordLength {
+		return fmt.Errorf("ach: entry detail record is %d characters, want %d", len(line), recordLength)
+	}
+	ed.RecordType = slice(line, 1, 2)
+	ed.TransactionCode = slice(line, 2, 4)
+	ed.RoutingNumber = slice(line, 4, 12)
+	ed.CheckDigit = slice(line, 12, 13)
+	ed.DfiAccountNumber = slice(line, 13, 30)
+	ed.Amount = slice(line, 30, 40)
+	ed.IndividualIdentificationNumber = slice(line, 40, 55)
+	ed.IndividualName = slice(line, 55, 77)
+	ed.DiscretionaryData = slice(line, 77, 79)
+	ed.AddendaRecordIndicator = slice(line, 79, 80)
+	ed.TraceNumber = slice(line, 80, 95)
+	return nil
+}
+
+// String renders ed as a 94 character Entry Detail Record line.
+func (ed EntryDetailRecord) String() string {
+	return entryDetailPos +
+		numeric(ed.TransactionCode, 2) +
+		numeric(ed.RoutingNumber, 8) +
+		numeric(ed.CheckDigit, 1) +
+		alpha(ed.DfiAccountNumber, 17) +
+		numeric(ed.Amount, 10) +
+		alpha(ed.IndividualIdentificationNumber, 15) +
+		alpha(ed.IndividualName, 22) +
+		alpha(ed.DiscretionaryData, 2) +
+		numeric(ed.AddendaRecordIndicator, 1) +
+		numeric(ed.TraceNumber, 15)
+}
+
+// parse populates bc from a 94 character Company/Batch Control Record line.
+func (bc *BatchControlRecord) parse(line string) error {
+	if len(line) < recordLength {
+		return fmt.Errorf("ach: batch control record is %d characters, want %d", len(line), recordLength)
+	}
+	bc.RecordType = slice(line, 1, 2)
+	bc.ServiceClassCode = slice(line, 2, 5)
+	bc.EntryAddendaCount = slice(line, 5, 11)
+	bc.EntryHash = slice(line, 11, 21)
+	bc.TotalDebitEntryDollarAmount = slice(line, 21, 33)
+	bc.TotalCreditEntryDollarAmount = slice(line, 33, 45)
+	bc.CompanyIdentification = slice(line, 45, 55)
+	bc.MessageAuthenticationCode = slice(line, 55, 74)
+	bc.Reserved = slice(line, 74, 80)
+	bc.RoutingNumber = slice(line, 80, 88)
+	bc.BatchNumber = slice(line, 88, 95)
+	return nil
+}
+
+// String renders bc as a 94 character Company/Batch Control Record line.
+func (bc BatchControlRecord) String() string {
+	return batchControlPos +
+		numeric(bc.ServiceClassCode, 3) +
+		numeric(bc.EntryAddendaCount, 6) +
+		numeric(bc.EntryHash, 10) +
+		numeric(bc.TotalDebitEntryDollarAmount, 12) +
+		numeric(bc.TotalCreditEntryDollarAmount, 12) +
+		alpha(bc.CompanyIdentification, 10) +
+		alpha(bc.MessageAuthenticationCode, 19) +
+		alpha(bc.Reserved, 6) +
+		numeric(bc.RoutingNumber, 8) +
+		numeric(bc.BatchNumber, 7)
+}
+
+// parse populates fc from a 94 character File Control Record line.
+func (fc *FileControlRecord) parse(line string) error {
+	if len(line) < recordLength {
+		return fmt.Errorf("ach: file control record is %d characters, want %d", len(line), recordLength)
+	}
+	fc.RecordType = slice(line, 1, 2)
+	fc.BatchCount = slice(line, 2, 8)
+	fc.BlockCount = slice(line, 8, 14)
+	fc.EntryAddendaCount = slice(line, 14, 22)
+	fc.EntryHash = slice(line, 22, 32)
+	fc.TotalDebitEntryDollarAmountInFile = slice(line, 32, 44)
+	fc.TotalCreditEntryDollarAmountInFile = slice(line, 44, 56)
+	fc.Reserved = slice(line, 56, 95)
+	return nil
+}
+
+// String renders fc as a 94 character File Control Record line.
+func (fc FileControlRecord) String() string {
+	return fileControlPos +
+		numeric(fc.BatchCount, 6) +
+		numeric(fc.BlockCount, 6) +
+		numeric(fc.EntryAddendaCount, 8) +
+		numeric(fc.EntryHash, 10) +
+		numeric(fc.TotalDebitEntryDollarAmountInFile, 12) +
+		numeric(fc.TotalCreditEntryDollarAmountInFile, 12) +
+		alpha(fc.Reserved, 39)
+}
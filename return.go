@@ -0,0 +1,133 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// transactionCodeFlip maps each TransactionCode to the one that reverses
+// its movement of funds: a credit becomes the matching debit and vice
+// versa. NewReturn uses this so a returned entry undoes the original
+// entry's effect rather than repeating it.
+var transactionCodeFlip = map[string]string{
+	"22": "27", "27": "22",
+	"23": "28", "28": "23",
+	"24": "29", "29": "24",
+	"32": "37", "37": "32",
+	"33": "38", "38": "33",
+	"34": "39", "39": "34",
+}
+
+// flipTransactionCode returns the TransactionCode that reverses code's
+// movement of funds, or code unchanged if it isn't one this package
+// recognizes.
+func flipTransactionCode(code string) string {
+	if flipped, ok := transactionCodeFlip[code]; ok {
+		return flipped
+	}
+	return code
+}
+
+// isValidCode reports whether code is shaped like "<prefix><01-max>", the
+// pattern NACHA uses for both Return Reason Codes (R01-R85) and
+// Notification of Change codes (C01-C69).
+func isValidCode(code string, prefix byte, max int) bool {
+	if len(code) != 3 || code[0] != prefix {
+		return false
+	}
+	n, err := strconv.Atoi(code[1:])
+	return err == nil && n >= 1 && n <= max
+}
+
+// isValidReturnReasonCode reports whether code is shaped like one of
+// NACHA's Return Reason Codes, R01 through R85.
+func isValidReturnReasonCode(code string) bool {
+	return isValidCode(code, 'R', 85)
+}
+
+// isValidChangeCode reports whether code is shaped like one of NACHA's
+// Notification of Change codes, C01 through C69.
+func isValidChangeCode(code string) bool {
+	return isValidCode(code, 'C', 69)
+}
+
+// Validate checks a.ChangeCode against NACHA's C01-C69 range, returning
+// ValidateErrors (or nil if a is clean).
+func (a Addenda98) Validate() error {
+	if !isValidChangeCode(a.ChangeCode) {
+		return ValidateErrors{&FieldError{"Addenda98", "ChangeCode", ErrMandatory,
+			fmt.Sprintf("%q is not a valid Notification of Change code (C01-C69)", a.ChangeCode)}}
+	}
+	return nil
+}
+
+// Validate checks a.ReturnReasonCode against NACHA's R01-R85 range,
+// returning ValidateErrors (or nil if a is clean).
+func (a Addenda99) Validate() error {
+	if !isValidReturnReasonCode(a.ReturnReasonCode) {
+		return ValidateErrors{&FieldError{"Addenda99", "ReturnReasonCode", ErrMandatory,
+			fmt.Sprintf("%q is not a valid Return Reason Code (R01-R85)", a.ReturnReasonCode)}}
+	}
+	return nil
+}
+
+// NewReturn builds the entry and Addenda99 an RDFI sends back to the ODFI
+// to return original unpaid: the TransactionCode is flipped so the
+// returned entry reverses the original's movement of funds, and the
+// original entry's TraceNumber is carried into the addenda's
+// OriginalEntryTraceNumber (positions 7-21 of the Addenda99 record) so the
+// ODFI can join it back to the entry it originated.
+func NewReturn(original EntryDetailRecord, code string) (EntryDetailRecord, Addenda99) {
+	entry := original
+	entry.TransactionCode = flipTransactionCode(original.TransactionCode)
+	entry.Addendas = nil
+
+	addenda := Addenda99{
+		ReturnReasonCode:                   code,
+		OriginalEntryTraceNumber:           original.TraceNumber,
+		OriginalReceivingDFIIdentification: original.RoutingNumber,
+	}
+	return entry, addenda
+}
+
+// ReturnedEntry pairs a returned EntryDetailRecord with the Addenda99 that
+// explains why it came back.
+type ReturnedEntry struct {
+	Entry   EntryDetailRecord
+	Addenda Addenda99
+}
+
+// Returns collects every entry in file carrying an Addenda99, across every
+// batch in file.Batches, as ReturnedEntry pairs. file.IATBatches are not
+// searched.
+func (file ACH) Returns() []ReturnedEntry {
+	var returns []ReturnedEntry
+	for _, batch := range file.Batches {
+		for _, entry := range batch.Entries {
+			for _, addenda := range entry.Addendas {
+				if a, ok := addenda.(*Addenda99); ok {
+					returns = append(returns, ReturnedEntry{Entry: entry, Addenda: *a})
+				}
+			}
+		}
+	}
+	return returns
+}
+
+// MatchReturn finds the EntryDetailRecord in outbound whose TraceNumber
+// matches addenda's OriginalEntryTraceNumber, letting an ODFI join an
+// inbound return back to the entry it originated.
+func (outbound ACH) MatchReturn(addenda Addenda99) (EntryDetailRecord, bool) {
+	for _, batch := range outbound.Batches {
+		for _, entry := range batch.Entries {
+			if entry.TraceNumber == addenda.OriginalEntryTraceNumber {
+				return entry, true
+			}
+		}
+	}
+	return EntryDetailRecord{}, false
+}
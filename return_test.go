@@ -0,0 +1,79 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import "testing"
+
+// TestNewReturn checks that NewReturn flips the original entry's
+// TransactionCode to reverse its movement of funds and copies its
+// TraceNumber and RoutingNumber into the Addenda99's
+// OriginalEntryTraceNumber/OriginalReceivingDFIIdentification fields.
+func TestNewReturn(t *testing.T) {
+	original := EntryDetailRecord{
+		TransactionCode: "22",
+		RoutingNumber:   "12345678",
+		TraceNumber:     "123456780000001",
+		Addendas:        []Addenda{&Addenda05{PaymentRelatedInformation: "memo"}},
+	}
+
+	entry, addenda := NewReturn(original, "R01")
+
+	if got, want := entry.TransactionCode, "27"; got != want {
+		t.Errorf("TransactionCode = %q, want %q (flipped credit-to-debit)", got, want)
+	}
+	if entry.Addendas != nil {
+		t.Errorf("Addendas = %v, want nil: a returned entry should not carry the original's addenda", entry.Addendas)
+	}
+	if got, want := addenda.ReturnReasonCode, "R01"; got != want {
+		t.Errorf("ReturnReasonCode = %q, want %q", got, want)
+	}
+	if got, want := addenda.OriginalEntryTraceNumber, original.TraceNumber; got != want {
+		t.Errorf("OriginalEntryTraceNumber = %q, want %q (original's TraceNumber)", got, want)
+	}
+	if got, want := addenda.OriginalReceivingDFIIdentification, original.RoutingNumber; got != want {
+		t.Errorf("OriginalReceivingDFIIdentification = %q, want %q (original's RoutingNumber)", got, want)
+	}
+}
+
+// TestBatchValidateRejectsInvalidReturnReasonCode checks that
+// Batch.Validate rejects a batch carrying an Addenda99 whose
+// ReturnReasonCode is outside NACHA's R01-R85 range.
+func TestBatchValidateRejectsInvalidReturnReasonCode(t *testing.T) {
+	b := Batch{
+		Header: BatchHeaderRecord{
+			ServiceClassCode:              "225",
+			StandardEntryClassCode:        "PPD",
+			EffectiveEntryDate:            "260101",
+			OriginatingOdfiIdentification: "12345678",
+			BatchNumber:                   "1",
+		},
+		Entries: []EntryDetailRecord{{
+			TransactionCode:  "27",
+			RoutingNumber:    "12345678",
+			CheckDigit:       "0",
+			DfiAccountNumber: "1",
+			Amount:           "100",
+			IndividualName:   "RECEIVER",
+			TraceNumber:      "123456780000001",
+			Addendas:         []Addenda{&Addenda99{ReturnReasonCode: "ZZZ"}},
+		}},
+		Control: BatchControlRecord{ServiceClassCode: "225", BatchNumber: "1"},
+	}
+
+	err := b.Validate()
+	if err == nil {
+		t.Fatal("Validate did not reject an Addenda99 with an invalid ReturnReasonCode")
+	}
+	ve, _ := err.(ValidateErrors)
+	found := false
+	for _, fe := range ve {
+		if fe.Record == "Addenda99" && fe.Field == "ReturnReasonCode" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Validate did not flag the Addenda99's ReturnReasonCode: %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorClass distinguishes how serious a field-level validation diagnostic
+// is, per the NACHA mandatory/required/optional (M/R/O) field-inclusion
+// model. Optional fields are never validated; they are simply skipped.
+type ErrorClass int
+
+const (
+	// ErrMandatory marks a diagnostic for a field the ODFI must reject the
+	// file for outright; the file cannot be transmitted as-is.
+	ErrMandatory ErrorClass = iota
+	// ErrRequired marks a diagnostic for a field that, left as written,
+	// would cause the RDFI to reject the individual entry on receipt. The
+	// file is still transmittable; this is a warning, not a hard failure.
+	ErrRequired
+)
+
+func (c ErrorClass) String() string {
+	if c == ErrRequired {
+		return "required"
+	}
+	return "mandatory"
+}
+
+// FieldError describes a single field that failed validation.
+type FieldError struct {
+	Record string
+	Field  string
+	Class  ErrorClass
+	Msg    string
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s.%s: %s (%s)", e.Record, e.Field, e.Msg, e.Class)
+}
+
+// ValidateErrors collects every FieldError found while validating a record
+// or an ACH file. A ValidateErrors is a hard reject if it contains at least
+// one ErrMandatory diagnostic; if every diagnostic is ErrRequired, the
+// record is merely carrying warnings that would cause the RDFI to reject
+// the affected entries on receipt.
+type ValidateErrors []*FieldError
+
+// Error implements the error interface, joining every diagnostic onto its
+// own line.
+func (e ValidateErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Rejected reports whether e contains a mandatory-field diagnostic, i.e.
+// whether the ODFI would reject the record outright.
+func (e ValidateErrors) Rejected() bool {
+	for _, fe := range e {
+		if fe.Class == ErrMandatory {
+			return true
+		}
+	}
+	return false
+}
+
+// merge flattens err's diagnostics, if any, onto dst.
+func merge(dst ValidateErrors, err error) ValidateErrors {
+	if err == nil {
+		return dst
+	}
+	if ve, ok := err.(ValidateErrors); ok {
+		return append(dst, ve...)
+	}
+	return dst
+}
+
+// knownServiceClassCodes are the ServiceClassCode values NACHA defines for
+// a batch: mixed debits and credits, credits only, and debits only.
+var knownServiceClassCodes = map[string]bool{
+	"200": true,
+	"220": true,
+	"225": true,
+}
+
+// knownSECCodes are the Standard Entry Class codes this package recognizes.
+var knownSECCodes = map[string]bool{
+	"PPD": true,
+	"CCD": true,
+	"CTX": true,
+	"WEB": true,
+	"TEL": true,
+	"ARC": true,
+	"BOC": true,
+	"IAT": true,
+}
+
+// achCheckDigit computes the check digit for an 8-digit ABA routing number
+// using the standard 3-7-1 weighting, repeated across the first eight
+// digits and reduced mod 10.
+func achCheckDigit(routingNumber string) (int, error) {
+	if len(routingNumber) != 8 {
+		return 0, fmt.Errorf("routing number %q must be 8 digits", routingNumber)
+	}
+	weights := [8]int{3, 7, 1, 3, 7, 1, 3, 7}
+	sum := 0
+	for i, w := range weights {
+		d := routingNumber[i]
+		if d < '0' || d > '9' {
+			return 0, fmt.Errorf("routing number %q contains a non-digit", routingNumber)
+		}
+		sum += int(d-'0') * w
+	}
+	return (10 - sum%10) % 10, nil
+}
+
+// validateRoutingField checks an 8-digit routing number against its
+// trailing check digit.
+func validateRoutingField(routingNumber, checkDigit string) error {
+	if checkDigit == "" {
+		return fmt.Errorf("check digit is missing")
+	}
+	want, err := achCheckDigit(routingNumber)
+	if err != nil {
+		return err
+	}
+	got, err := strconv.Atoi(checkDigit)
+	if err != nil {
+		return fmt.Errorf("check digit %q is not numeric", checkDigit)
+	}
+	if want != got {
+		return fmt.Errorf("check digit %d does not match computed check digit %d", got, want)
+	}
+	return nil
+}
+
+// parseImmediateField splits a 10-character ImmediateDestination or
+// ImmediateOrigin field (shape bTTTTAAAAC: a leading blank, an 8-digit ABA
+// routing number, and a trailing check digit) into its routing number and
+// check digit.
+func parseImmediateField(field string) (routingNumber, checkDigit string, err error) {
+	if len(field) != 10 {
+		return "", "", fmt.Errorf("must be 10 characters, got %d", len(field))
+	}
+	return field[1:9], field[9:10], nil
+}
+
+// Validate checks fh against the NACHA field-inclusion rules for a File
+// Header Record, returning ValidateErrors (or nil if fh is clean).
+func (fh FileHeaderRecord) Validate() error {
+	var errs ValidateErrors
+
+	if fh.RecordType != headerPos {
+		errs = append(errs, &FieldError{"FileHeaderRecord", "RecordType", ErrMandatory,
+			fmt.Sprintf("must be %q, got %q", headerPos, fh.RecordType)})
+	}
+	for _, f := range []struct{ name, value string }{
+		{"ImmediateDestination", fh.ImmediateDestination},
+		{"ImmediateOrigin", fh.ImmediateOrigin},
+	} {
+		routingNumber, checkDigit, err := parseImmediateField(f.value)
+		if err != nil {
+			errs = append(errs, &FieldError{"FileHeaderRecord", f.name, ErrMandatory, err.Error()})
+			continue
+		}
+		if err := validateRoutingField(routingNumber, checkDigit); err != nil {
+			errs = append(errs, &FieldError{"FileHeaderRecord", f.name, ErrMandatory, err.Error()})
+		}
+	}
+	if _, err := time.Parse("060102", fh.FileCreationDate); err != nil {
+		errs = append(errs, &FieldError{"FileHeaderRecord", "FileCreationDate", ErrRequired,
+			fmt.Sprintf("not a valid YYMMDD date: %v", err)})
+	}
+	if _, err := time.Parse("1504", fh.FileCreationTime); err != nil {
+		errs = append(errs, &FieldError{"FileHeaderRecord", "FileCreationTime", ErrRequired,
+			fmt.Sprintf("not a valid HHMM time: %v", err)})
+	}
+	if fh.RecordSize != "094" {
+		errs = append(errs, &FieldError{"FileHeaderRecord", "RecordSize", ErrMandatory,
+			fmt.Sprintf("must be \"094\", got %q", fh.RecordSize)})
+	}
+	if fh.BlockingFactor != "10" {
+		errs = append(errs, &FieldError{"FileHeaderRecord", "BlockingFactor", ErrMandatory,
+			fmt.Sprintf("must be \"10\", got %q", fh.BlockingFactor)})
+	}
+	if fh.FormatCode != "1" {
+		errs = append(errs, &FieldError{"FileHeaderRecord", "FormatCode", ErrMandatory,
+			fmt.Sprintf("must be \"1\", got %q", fh.FormatCode)})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks bh against the NACHA field-inclusion rules for a
+// Company/Batch Header Record, returning ValidateErrors (or nil if bh is
+// clean).
+func (bh BatchHeaderRecord) Validate() error {
+	var errs ValidateErrors
+
+	if bh.RecordType != batchPos {
+		errs = append(errs, &FieldError{"BatchHeaderRecord", "RecordType", ErrMandatory,
+			fmt.Sprintf("must be %q, got %q", batchPos, bh.RecordType)})
+	}
+	if !knownServiceClassCodes[bh.ServiceClassCode] {
+		errs = append(errs, &FieldError{"BatchHeaderRecord", "ServiceClassCode", ErrMandatory,
+			fmt.Sprintf("%q is not one of 200, 220, 225", bh.ServiceClassCode)})
+	}
+	if !knownSECCodes[bh.StandardEntryClassCode] {
+		errs = append(errs, &FieldError{"BatchHeaderRecord", "StandardEntryClassCode", ErrMandatory,
+			fmt.Sprintf("%q is not a recognized Standard Entry Class code", bh.StandardEntryClassCode)})
+	}
+	if _, err := time.Parse("060102", bh.EffectiveEntryDate); err != nil {
+		errs = append(errs, &FieldError{"BatchHeaderRecord", "EffectiveEntryDate", ErrRequired,
+			fmt.Sprintf("not a valid YYMMDD date: %v", err)})
+	}
+	if len(bh.OriginatingOdfiIdentification) != 8 {
+		errs = append(errs, &FieldError{"BatchHeaderRecord", "OriginatingOdfiIdentification", ErrMandatory,
+			fmt.Sprintf("must be 8 digits, got %q", bh.OriginatingOdfiIdentification)})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// knownTransactionCodes are the TransactionCode values this package
+// recognizes: checking/savings credits, debits, and their prenote variants.
+var knownTransactionCodes = map[string]bool{
+	"22": true, "23": true, "24": true, "27": true, "28": true, "29": true,
+	"32": true, "33": true, "34": true, "37": true, "38": true, "39": true,
+}
+
+// Validate checks ed against the NACHA field-inclusion rules for an Entry
+// Detail Record, returning ValidateErrors (or nil if ed is clean).
+func (ed EntryDetailRecord) Validate() error {
+	var errs ValidateErrors
+
+	if ed.RecordType != entryDetailPos {
+		errs = append(errs, &FieldError{"EntryDetailRecord", "RecordType", ErrMandatory,
+			fmt.Sprintf("must be %q, got %q", entryDetailPos, ed.RecordType)})
+	}
+	if !knownTransactionCodes[ed.TransactionCode] {
+		errs = append(errs, &FieldError{"EntryDetailRecord", "TransactionCode", ErrMandatory,
+			fmt.Sprintf("%q is not a recognized transaction code", ed.TransactionCode)})
+	}
+	if err := validateRoutingField(ed.RoutingNumber, ed.CheckDigit); err != nil {
+		errs = append(errs, &FieldError{"EntryDetailRecord", "RoutingNumber", ErrMandatory, err.Error()})
+	}
+	if _, err := strconv.Atoi(strings.TrimSpace(ed.Amount)); err != nil {
+		errs = append(errs, &FieldError{"EntryDetailRecord", "Amount", ErrMandatory,
+			fmt.Sprintf("must be numeric, got %q", ed.Amount)})
+	}
+	if strings.TrimSpace(ed.DfiAccountNumber) == "" {
+		errs = append(errs, &FieldError{"EntryDetailRecord", "DfiAccountNumber", ErrMandatory, "is required"})
+	}
+	if strings.TrimSpace(ed.IndividualName) == "" {
+		errs = append(errs, &FieldError{"EntryDetailRecord", "IndividualName", ErrRequired, "is blank"})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks bc against the NACHA field-inclusion rules for a
+// Company/Batch Control Record, returning ValidateErrors (or nil if bc is
+// clean).
+func (bc BatchControlRecord) Validate() error {
+	var errs ValidateErrors
+
+	if bc.RecordType != batchControlPos {
+		errs = append(errs, &FieldError{"BatchControlRecord", "RecordType", ErrMandatory,
+			fmt.Sprintf("must be %q, got %q", batchControlPos, bc.RecordType)})
+	}
+	if !knownServiceClassCodes[bc.ServiceClassCode] {
+		errs = append(errs, &FieldError{"BatchControlRecord", "ServiceClassCode", ErrMandatory,
+			fmt.Sprintf("%q is not one of 200, 220, 225", bc.ServiceClassCode)})
+	}
+	for _, f := range []struct{ name, value string }{
+		{"EntryAddendaCount", bc.EntryAddendaCount},
+		{"EntryHash", bc.EntryHash},
+		{"TotalDebitEntryDollarAmount", bc.TotalDebitEntryDollarAmount},
+		{"TotalCreditEntryDollarAmount", bc.TotalCreditEntryDollarAmount},
+	} {
+		if _, err := strconv.Atoi(strings.TrimSpace(f.value)); err != nil {
+			errs = append(errs, &FieldError{"BatchControlRecord", f.name, ErrMandatory,
+				fmt.Sprintf("must be numeric, got %q", f.value)})
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks fc against the NACHA field-inclusion rules for a File
+// Control Record, returning ValidateErrors (or nil if fc is clean).
+func (fc FileControlRecord) Validate() error {
+	var errs ValidateErrors
+
+	if fc.RecordType != fileControlPos {
+		errs = append(errs, &FieldError{"FileControlRecord", "RecordType", ErrMandatory,
+			fmt.Sprintf("must be %q, got %q", fileControlPos, fc.RecordType)})
+	}
+	for _, f := range []struct{ name, value string }{
+		{"BatchCount", fc.BatchCount},
+		{"BlockCount", fc.BlockCount},
+		{"EntryAddendaCount", fc.EntryAddendaCount},
+		{"EntryHash", fc.EntryHash},
+		{"TotalDebitEntryDollarAmountInFile", fc.TotalDebitEntryDollarAmountInFile},
+		{"TotalCreditEntryDollarAmountInFile", fc.TotalCreditEntryDollarAmountInFile},
+	} {
+		if _, err := strconv.Atoi(strings.TrimSpace(f.value)); err != nil {
+			errs = append(errs, &FieldError{"FileControlRecord", f.name, ErrMandatory,
+				fmt.Sprintf("must be numeric, got %q", f.value)})
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks every record in file, including the consistency between
+// each batch's header and control records, and returns the accumulated
+// ValidateErrors (or nil if the file is clean).
+func (file ACH) Validate() error {
+	var errs ValidateErrors
+
+	errs = merge(errs, file.FileHeader.Validate())
+	for _, batch := range file.Batches {
+		errs = merge(errs, batch.Validate())
+	}
+	for _, iatBatch := range file.IATBatches {
+		errs = merge(errs, iatBatch.Validate())
+	}
+	errs = merge(errs, file.FileControl.Validate())
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// fillerRecord is the all-'9' record NACHA uses to pad a file out to a
+// multiple of the blocking factor.
+var fillerRecord = strings.Repeat("9", recordLength)
+
+// Writer writes an ACH file in the 94-character fixed-width NACHA format.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter returns a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Write serializes file, including its File Header, each Batch and IATBatch
+// (header, entries with their addenda, and control), the File Control
+// record, and trailing filler records out to a multiple of the file's
+// BlockingFactor. Lines are terminated with CRLF, as is conventional for
+// NACHA files.
+func (w *Writer) Write(file ACH) error {
+	lines := 0
+
+	writeLine := func(s string) error {
+		if _, err := w.w.WriteString(s); err != nil {
+			return err
+		}
+		if _, err := w.w.WriteString("\r\n"); err != nil {
+			return err
+		}
+		lines++
+		return nil
+	}
+
+	if err := writeLine(file.FileHeader.String()); err != nil {
+		return err
+	}
+
+	for _, batch := range file.Batches {
+		if err := writeLine(batch.Header.String()); err != nil {
+			return err
+		}
+		for _, entry := range batch.Entries {
+			if err := writeLine(entry.String()); err != nil {
+				return err
+			}
+			for _, addenda := range entry.Addendas {
+				if err := writeLine(addenda.String()); err != nil {
+					return err
+				}
+			}
+		}
+		if err := writeLine(batch.Control.String()); err != nil {
+			return err
+		}
+	}
+
+	for _, batch := range file.IATBatches {
+		if err := writeLine(batch.Header.String()); err != nil {
+			return err
+		}
+		for _, entry := range batch.Entries {
+			if err := writeLine(entry.EntryDetail.String()); err != nil {
+				return err
+			}
+			for _, addenda := range entry.Addenda {
+				if err := writeLine(addenda.String()); err != nil {
+					return err
+				}
+			}
+		}
+		if err := writeLine(batch.Control.String()); err != nil {
+			return err
+		}
+	}
+
+	if err := writeLine(file.FileControl.String()); err != nil {
+		return err
+	}
+
+	blockingFactor, err := strconv.Atoi(strings.TrimSpace(file.FileHeader.BlockingFactor))
+	if err != nil || blockingFactor <= 0 {
+		blockingFactor = 10
+	}
+	for lines%blockingFactor != 0 {
+		if err := writeLine(fillerRecord); err != nil {
+			return err
+		}
+	}
+
+	return w.w.Flush()
+}
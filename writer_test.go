@@ -0,0 +1,59 @@
+// Copyright 2016 The ACH Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ach
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFillerRecordLength(t *testing.T) {
+	if len(fillerRecord) != recordLength {
+		t.Fatalf("fillerRecord is %d characters, want %d", len(fillerRecord), recordLength)
+	}
+	if strings.Trim(fillerRecord, "9") != "" {
+		t.Fatalf("fillerRecord contains a non-'9' character: %q", fillerRecord)
+	}
+}
+
+// TestWriterPadsToBlockingFactor builds a one-entry, one-batch file and
+// checks that Write pads every line, including the filler records, out to
+// recordLength and to a multiple of the file's BlockingFactor.
+func TestWriterPadsToBlockingFactor(t *testing.T) {
+	file := ACH{
+		FileHeader: FileHeaderRecord{BlockingFactor: "10"},
+		Batches: []Batch{
+			{
+				Header:  BatchHeaderRecord{},
+				Entries: []EntryDetailRecord{{}},
+				Control: BatchControlRecord{},
+			},
+		},
+		FileControl: FileControlRecord{},
+	}
+
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Write(file); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\r\n"), "\r\n")
+	if len(lines)%10 != 0 {
+		t.Fatalf("wrote %d lines, want a multiple of 10", len(lines))
+	}
+	for i, line := range lines {
+		if len(line) != recordLength {
+			t.Fatalf("line %d is %d characters, want %d: %q", i, len(line), recordLength, line)
+		}
+	}
+	// file header, entry, batch header, batch control, file control = 5
+	// non-filler lines, so 5 filler lines pad out to the next multiple of 10.
+	for _, line := range lines[5:] {
+		if !isFillerRecord(line) {
+			t.Fatalf("line %q is not a filler record", line)
+		}
+	}
+}